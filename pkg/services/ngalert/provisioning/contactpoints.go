@@ -1,11 +1,21 @@
 package provisioning
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -13,14 +23,70 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/prometheus/alertmanager/config"
+	"gopkg.in/yaml.v3"
 )
 
+// Secure-setting keys carrying mutual-TLS client certificate material.
+const (
+	tlsConfigClientCertKey = "tls_config.client_cert"
+	tlsConfigClientKeyKey  = "tls_config.client_key"
+	tlsConfigCACertKey     = "tls_config.ca_cert"
+)
+
+// tlsCertExpiryWarning is how far out from expiry a client certificate starts
+// logging a warning, so operators notice before delivery starts failing.
+const tlsCertExpiryWarning = 30 * 24 * time.Hour
+
+// maxConfigWriteAttempts bounds withRetry's read-modify-write retries.
+const maxConfigWriteAttempts = 5
+
+var ErrConcurrentModification = errors.New("contact point could not be saved: configuration was concurrently modified, please try again")
+
+// testContactPointTimeout bounds how long TestContactPoint waits on a single
+// receiver integration to accept the synthetic alert.
+const testContactPointTimeout = 10 * time.Second
+
+// ContactPointTestAlert is a synthetic alert used to exercise a contact
+// point's delivery path without a real alert rule having fired.
+type ContactPointTestAlert struct {
+	// State is either "firing" or "resolved".
+	State       string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// ContactPointNotifier is the minimal surface a receiver integration needs to
+// expose for TestContactPoint to dispatch a synthetic alert through it.
+type ContactPointNotifier interface {
+	Notify(ctx context.Context, alert ContactPointTestAlert) error
+}
+
+// ContactPointNotifierFactory builds a notifier for a single Grafana-managed
+// receiver, decoupled from the running Alertmanager, so contact points can be
+// dry-run tested in isolation.
+type ContactPointNotifierFactory interface {
+	BuildNotifier(ctx context.Context, receiver *apimodels.PostableGrafanaReceiver) (ContactPointNotifier, error)
+}
+
+// ContactPointTestResult is the outcome of dispatching a synthetic alert
+// through a single contact point integration.
+type ContactPointTestResult struct {
+	Name    string
+	Type    string
+	Success bool
+	Error   string
+}
+
 type EmbeddedContactPointService struct {
 	amStore           store.AlertingStore
 	encryptionService secrets.Service
 	provenanceStore   ProvisioningStore
 	xact              TransactionManager
+	notifierFactory   ContactPointNotifierFactory
 	log               log.Logger
+
+	orgMtxMu sync.Mutex
+	orgMtx   map[int64]*sync.Mutex
 }
 
 func NewEmbeddedContactPointService(store store.AlertingStore, encryptionService secrets.Service,
@@ -30,8 +96,141 @@ func NewEmbeddedContactPointService(store store.AlertingStore, encryptionService
 		encryptionService: encryptionService,
 		provenanceStore:   provenanceStore,
 		xact:              xact,
+		notifierFactory:   newWebhookNotifierFactory(),
 		log:               log,
+		orgMtx:            map[int64]*sync.Mutex{},
+	}
+}
+
+// SetNotifierFactory overrides the default webhook-only notifier factory,
+// for callers that need TestContactPoint to exercise other receiver types.
+func (ecp *EmbeddedContactPointService) SetNotifierFactory(f ContactPointNotifierFactory) {
+	ecp.notifierFactory = f
+}
+
+// webhookNotifierFactory builds a notifier that POSTs the test alert as JSON
+// to the receiver's "url" setting.
+type webhookNotifierFactory struct {
+	client *http.Client
+}
+
+func newWebhookNotifierFactory() *webhookNotifierFactory {
+	return &webhookNotifierFactory{client: &http.Client{Timeout: testContactPointTimeout}}
+}
+
+func (f *webhookNotifierFactory) BuildNotifier(ctx context.Context, receiver *apimodels.PostableGrafanaReceiver) (ContactPointNotifier, error) {
+	url := receiver.Settings.Get("url").MustString()
+	if url == "" {
+		return nil, fmt.Errorf("receiver type %q has no \"url\" setting to test against", receiver.Type)
+	}
+	return &webhookNotifier{client: f.client, url: url}, nil
+}
+
+type webhookNotifier struct {
+	client *http.Client
+	url    string
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert ContactPointTestAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// lockOrg serializes writes to a single org's Alertmanager configuration.
+// Call the returned func to unlock.
+func (ecp *EmbeddedContactPointService) lockOrg(orgID int64) func() {
+	ecp.orgMtxMu.Lock()
+	mtx, ok := ecp.orgMtx[orgID]
+	if !ok {
+		mtx = &sync.Mutex{}
+		ecp.orgMtx[orgID] = mtx
 	}
+	ecp.orgMtxMu.Unlock()
+
+	mtx.Lock()
+	return mtx.Unlock
+}
+
+// terminalError marks a withRetry attempt error as non-retryable.
+type terminalError struct{ err error }
+
+func (t terminalError) Error() string { return t.err.Error() }
+func (t terminalError) Unwrap() error { return t.err }
+
+// retryBackoff returns an increasing delay with jitter between retries.
+func retryBackoff(attempt int) time.Duration {
+	base := time.Duration(attempt+1) * 20 * time.Millisecond
+	jitter := time.Duration(rand.Int63n(int64(20 * time.Millisecond)))
+	return base + jitter
+}
+
+// withRetry serializes writes to orgID's Alertmanager configuration behind
+// lockOrg, then re-reads the configuration and calls attempt until it
+// succeeds, returns a terminalError, or maxConfigWriteAttempts is exhausted
+// (in which case it returns ErrConcurrentModification). An attempt failure is
+// only retried if isWriteConflict confirms it was actually caused by the
+// configuration changing out from under fetchedHash; anything else is
+// returned to the caller immediately instead of being retried.
+func (ecp *EmbeddedContactPointService) withRetry(ctx context.Context, orgID int64, attempt func(cfg *apimodels.PostableUserConfig, fetchedHash string) error) error {
+	unlock := ecp.lockOrg(orgID)
+	defer unlock()
+
+	var lastErr error
+	for i := 0; i < maxConfigWriteAttempts; i++ {
+		cfg, fetchedHash, err := ecp.getCurrentConfig(ctx, orgID)
+		if err != nil {
+			return err
+		}
+
+		lastErr = attempt(cfg, fetchedHash)
+		if lastErr == nil {
+			return nil
+		}
+
+		var term terminalError
+		if errors.As(lastErr, &term) {
+			return term.err
+		}
+
+		if !ecp.isWriteConflict(ctx, orgID, fetchedHash) {
+			return lastErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryBackoff(i)):
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrConcurrentModification, lastErr)
+}
+
+// isWriteConflict reports whether orgID's stored configuration hash has
+// moved on from fetchedHash, i.e. some other writer committed in between.
+func (ecp *EmbeddedContactPointService) isWriteConflict(ctx context.Context, orgID int64, fetchedHash string) bool {
+	_, currentHash, err := ecp.getCurrentConfig(ctx, orgID)
+	if err != nil {
+		return false
+	}
+	return currentHash != fetchedHash
 }
 
 func (ecp *EmbeddedContactPointService) GetContactPoints(ctx context.Context, orgID int64) ([]apimodels.EmbeddedContactPoint, error) {
@@ -109,19 +308,19 @@ func (ecp *EmbeddedContactPointService) getContactPointUncrypted(ctx context.Con
 func (ecp *EmbeddedContactPointService) CreateContactPoint(ctx context.Context, orgID int64,
 	contactPoint apimodels.EmbeddedContactPoint, provenance models.Provenance) (apimodels.EmbeddedContactPoint, error) {
 
-	if err := contactPoint.IsValid(ecp.encryptionService.GetDecryptedValue); err != nil {
-		return apimodels.EmbeddedContactPoint{}, fmt.Errorf("contact point is not valid: %w", err)
-	}
-
-	cfg, fetchedHash, err := ecp.getCurrentConfig(ctx, orgID)
-	if err != nil {
+	if err := ecp.validateContactPoint(contactPoint); err != nil {
 		return apimodels.EmbeddedContactPoint{}, err
 	}
 
+	tlsSecrets := extractTLSSecrets(contactPoint)
+
 	extracedSecrets, err := contactPoint.ExtractSecrtes()
 	if err != nil {
 		return apimodels.EmbeddedContactPoint{}, err
 	}
+	for k, v := range tlsSecrets {
+		extracedSecrets[k] = v
+	}
 
 	for k, v := range extracedSecrets {
 		encryptedValue, err := ecp.encryptValue(v)
@@ -141,51 +340,53 @@ func (ecp *EmbeddedContactPointService) CreateContactPoint(ctx context.Context,
 		SecureSettings:        extracedSecrets,
 	}
 
-	receiverFound := false
-	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
-		if receiver.Name == contactPoint.Name {
-			receiver.PostableGrafanaReceivers.GrafanaManagedReceivers = append(receiver.PostableGrafanaReceivers.GrafanaManagedReceivers, grafanaReceiver)
-			receiverFound = true
+	err = ecp.withRetry(ctx, orgID, func(cfg *apimodels.PostableUserConfig, fetchedHash string) error {
+		receiverFound := false
+		for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+			if receiver.Name == contactPoint.Name {
+				receiver.PostableGrafanaReceivers.GrafanaManagedReceivers = append(receiver.PostableGrafanaReceivers.GrafanaManagedReceivers, grafanaReceiver)
+				receiverFound = true
+			}
 		}
-	}
-
-	if !receiverFound {
-		cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, &apimodels.PostableApiReceiver{
-			Receiver: config.Receiver{
-				Name: grafanaReceiver.Name,
-			},
-			PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
-				GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{grafanaReceiver},
-			},
-		})
-	}
 
-	data, err := json.Marshal(cfg)
-	if err != nil {
-		return apimodels.EmbeddedContactPoint{}, err
-	}
-
-	err = ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
-		err = ecp.amStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
-			AlertmanagerConfiguration: string(data),
-			FetchedConfigurationHash:  fetchedHash,
-			ConfigurationVersion:      "v1",
-			Default:                   false,
-			OrgID:                     orgID,
-		})
-		if err != nil {
-			return err
-		}
-		adapter := provenanceOrgAdapter{
-			inner: &contactPoint,
-			orgID: orgID,
+		if !receiverFound {
+			cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, &apimodels.PostableApiReceiver{
+				Receiver: config.Receiver{
+					Name: grafanaReceiver.Name,
+				},
+				PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+					GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{grafanaReceiver},
+				},
+			})
 		}
-		err = ecp.provenanceStore.SetProvenance(ctx, adapter, provenance)
+
+		data, err := json.Marshal(cfg)
 		if err != nil {
-			return err
+			return terminalError{err}
 		}
-		contactPoint.Provenance = string(provenance)
-		return nil
+
+		return ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
+			err := ecp.amStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+				AlertmanagerConfiguration: string(data),
+				FetchedConfigurationHash:  fetchedHash,
+				ConfigurationVersion:      "v1",
+				Default:                   false,
+				OrgID:                     orgID,
+			})
+			if err != nil {
+				return err
+			}
+			adapter := provenanceOrgAdapter{
+				inner: &contactPoint,
+				orgID: orgID,
+			}
+			err = ecp.provenanceStore.SetProvenance(ctx, adapter, provenance)
+			if err != nil {
+				return err
+			}
+			contactPoint.Provenance = string(provenance)
+			return nil
+		})
 	})
 	if err != nil {
 		return apimodels.EmbeddedContactPoint{}, err
@@ -193,15 +394,17 @@ func (ecp *EmbeddedContactPointService) CreateContactPoint(ctx context.Context,
 	return contactPoint, nil
 }
 
-func (ecp *EmbeddedContactPointService) UpdateContactPoint(ctx context.Context, orgID int64, contactPoint apimodels.EmbeddedContactPoint) error {
-	// set all redacted values with the latest known value from the store
+// mergeRedactedValues replaces any setting on contactPoint that's still the
+// redacted placeholder with the real value from the stored contact point
+// matching its UID.
+func (ecp *EmbeddedContactPointService) mergeRedactedValues(ctx context.Context, orgID int64, contactPoint apimodels.EmbeddedContactPoint) (apimodels.EmbeddedContactPoint, error) {
 	rawContactPoint, err := ecp.getContactPointUncrypted(ctx, orgID, contactPoint.UID)
 	if err != nil {
-		return err
+		return apimodels.EmbeddedContactPoint{}, err
 	}
 	secretKeys, err := contactPoint.SecretKeys()
 	if err != nil {
-		return err
+		return apimodels.EmbeddedContactPoint{}, err
 	}
 	for _, secretKey := range secretKeys {
 		secretValue := contactPoint.Settings.Get(secretKey).MustString()
@@ -209,15 +412,37 @@ func (ecp *EmbeddedContactPointService) UpdateContactPoint(ctx context.Context,
 			contactPoint.Settings.Set(secretKey, rawContactPoint.Settings.Get(secretKey).MustString())
 		}
 	}
-	// validate merged values
+	mergeRedactedTLSSecrets(contactPoint, rawContactPoint)
+	return contactPoint, nil
+}
+
+// validateContactPoint runs the same checks CreateContactPoint and
+// UpdateContactPoint apply before writing, so callers that only need to know
+// whether a contact point is valid (e.g. an import dry run) can reuse them.
+func (ecp *EmbeddedContactPointService) validateContactPoint(contactPoint apimodels.EmbeddedContactPoint) error {
 	if err := contactPoint.IsValid(ecp.encryptionService.GetDecryptedValue); err != nil {
+		return fmt.Errorf("contact point is not valid: %w", err)
+	}
+	return ecp.validateTLSConfig(contactPoint)
+}
+
+func (ecp *EmbeddedContactPointService) UpdateContactPoint(ctx context.Context, orgID int64, contactPoint apimodels.EmbeddedContactPoint, provenance models.Provenance) error {
+	contactPoint, err := ecp.mergeRedactedValues(ctx, orgID, contactPoint)
+	if err != nil {
+		return err
+	}
+	if err := ecp.validateContactPoint(contactPoint); err != nil {
 		return err
 	}
 	// transform to internal model
+	tlsSecrets := extractTLSSecrets(contactPoint)
 	extracedSecrets, err := contactPoint.ExtractSecrtes()
 	if err != nil {
 		return err
 	}
+	for k, v := range tlsSecrets {
+		extracedSecrets[k] = v
+	}
 	for k, v := range extracedSecrets {
 		encryptedValue, err := ecp.encryptValue(v)
 		if err != nil {
@@ -234,68 +459,466 @@ func (ecp *EmbeddedContactPointService) UpdateContactPoint(ctx context.Context,
 		SecureSettings:        extracedSecrets,
 	}
 	// save to store
-	cfg, fetchedHash, err := ecp.getCurrentConfig(ctx, orgID)
-	if err != nil {
-		return err
-	}
-	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
-		if receiver.Name == contactPoint.Name {
-			receiverNotFound := true
-			for i, grafanaReceiver := range receiver.GrafanaManagedReceivers {
-				if grafanaReceiver.UID == mergedReceiver.UID {
-					receiverNotFound = false
-					receiver.GrafanaManagedReceivers[i] = mergedReceiver
+	return ecp.withRetry(ctx, orgID, func(cfg *apimodels.PostableUserConfig, fetchedHash string) error {
+		for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+			if receiver.Name == contactPoint.Name {
+				receiverNotFound := true
+				for i, grafanaReceiver := range receiver.GrafanaManagedReceivers {
+					if grafanaReceiver.UID == mergedReceiver.UID {
+						receiverNotFound = false
+						receiver.GrafanaManagedReceivers[i] = mergedReceiver
+						break
+					}
+				}
+				if receiverNotFound {
+					return terminalError{fmt.Errorf("contact point with uid '%s' not found", mergedReceiver.UID)}
+				}
+			}
+		}
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return terminalError{err}
+		}
+		return ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
+			err := ecp.amStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+				AlertmanagerConfiguration: string(data),
+				FetchedConfigurationHash:  fetchedHash,
+				ConfigurationVersion:      "v1",
+				Default:                   false,
+				OrgID:                     orgID,
+			})
+			if err != nil {
+				return err
+			}
+			adapter := provenanceOrgAdapter{
+				inner: &contactPoint,
+				orgID: orgID,
+			}
+			return ecp.provenanceStore.SetProvenance(ctx, adapter, provenance)
+		})
+	})
+}
+
+func (ecp *EmbeddedContactPointService) DeleteContactPoint(ctx context.Context, orgID int64, uid string) error {
+	return ecp.withRetry(ctx, orgID, func(cfg *apimodels.PostableUserConfig, fetchedHash string) error {
+		for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+			for index, grafanaReceiver := range receiver.GrafanaManagedReceivers {
+				if grafanaReceiver.UID == uid {
+					receiver.GrafanaManagedReceivers = append(receiver.GrafanaManagedReceivers[:index], receiver.GrafanaManagedReceivers[index+1:]...)
 					break
 				}
 			}
-			if receiverNotFound {
-				return fmt.Errorf("contact point with uid '%s' not found", mergedReceiver.UID)
+		}
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return terminalError{err}
+		}
+		return ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
+			err := ecp.provenanceStore.DeleteProvenance(ctx, orgID, &apimodels.EmbeddedContactPoint{
+				UID: uid,
+			})
+			if err != nil {
+				return err
 			}
+			return ecp.amStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+				AlertmanagerConfiguration: string(data),
+				FetchedConfigurationHash:  fetchedHash,
+				ConfigurationVersion:      "v1",
+				Default:                   false,
+				OrgID:                     orgID,
+			})
+		})
+	})
+}
+
+// TestContactPoint dispatches a synthetic firing alert, immediately followed
+// by its resolved counterpart, through contactPoint without touching the
+// org's stored Alertmanager configuration.
+func (ecp *EmbeddedContactPointService) TestContactPoint(ctx context.Context, orgID int64, contactPoint apimodels.EmbeddedContactPoint) (ContactPointTestResult, error) {
+	if contactPoint.UID != "" {
+		var err error
+		contactPoint, err = ecp.mergeRedactedValues(ctx, orgID, contactPoint)
+		if err != nil {
+			return ContactPointTestResult{}, err
 		}
 	}
-	data, err := json.Marshal(cfg)
+
+	if err := ecp.validateContactPoint(contactPoint); err != nil {
+		return ContactPointTestResult{}, err
+	}
+
+	// secrets here are kept decrypted: the receiver only ever lives in memory
+	// for the duration of the test and is never persisted or marshaled.
+	tlsSecrets := extractTLSSecrets(contactPoint)
+	extracedSecrets, err := contactPoint.ExtractSecrtes()
 	if err != nil {
-		return err
+		return ContactPointTestResult{}, err
 	}
-	return ecp.amStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
-		AlertmanagerConfiguration: string(data),
-		FetchedConfigurationHash:  fetchedHash,
-		ConfigurationVersion:      "v1",
-		Default:                   false,
-		OrgID:                     orgID,
-	})
+	for k, v := range tlsSecrets {
+		extracedSecrets[k] = v
+	}
+	receiver := &apimodels.PostableGrafanaReceiver{
+		UID:                   contactPoint.UID,
+		Name:                  contactPoint.Name,
+		Type:                  contactPoint.Type,
+		DisableResolveMessage: contactPoint.DisableResolveMessage,
+		Settings:              contactPoint.Settings,
+		SecureSettings:        extracedSecrets,
+	}
+
+	notifier, err := ecp.notifierFactory.BuildNotifier(ctx, receiver)
+	if err != nil {
+		return ContactPointTestResult{}, fmt.Errorf("failed to build notifier for contact point: %w", err)
+	}
+
+	testCtx, cancel := context.WithTimeout(ctx, testContactPointTimeout)
+	defer cancel()
+
+	result := ContactPointTestResult{Name: contactPoint.Name, Type: contactPoint.Type}
+	for _, alert := range syntheticTestAlerts() {
+		if err := notifier.Notify(testCtx, alert); err != nil {
+			result.Error = redactSecretValues(err.Error(), extracedSecrets)
+			return result, nil
+		}
+	}
+	result.Success = true
+	return result, nil
 }
 
-func (ecp *EmbeddedContactPointService) DeleteContactPoint(ctx context.Context, orgID int64, uid string) error {
-	cfg, fetchedHash, err := ecp.getCurrentConfig(ctx, orgID)
+// syntheticTestAlerts returns a firing alert immediately followed by its
+// resolved counterpart, matching how a real alert rule notifies a contact
+// point across its lifecycle.
+func syntheticTestAlerts() []ContactPointTestAlert {
+	labels := map[string]string{"alertname": "TestAlert"}
+	annotations := map[string]string{"summary": "Test alert dispatched from Grafana"}
+	return []ContactPointTestAlert{
+		{State: "firing", Labels: labels, Annotations: annotations},
+		{State: "resolved", Labels: labels, Annotations: annotations},
+	}
+}
+
+// redactSecretValues scrubs any secret value that made it into an error
+// message (e.g. a webhook URL containing a token) before it's returned to the
+// caller.
+func redactSecretValues(msg string, secrets map[string]string) string {
+	for _, v := range secrets {
+		if v == "" {
+			continue
+		}
+		msg = strings.ReplaceAll(msg, v, apimodels.RedactedValue)
+	}
+	return msg
+}
+
+// ImportMode controls how ImportContactPoints reconciles an incoming
+// contact-point set against what's already stored for the org.
+type ImportMode string
+
+const (
+	// ImportModeMerge updates a stored contact point that matches an imported
+	// one by UID, or by (name, type) when the imported one has no UID, and
+	// creates the unmatched rest. An imported UID with no stored match, or a
+	// (name, type) pair shared by several stored contact points, is skipped.
+	// It never deletes.
+	ImportModeMerge ImportMode = "merge"
+	// ImportModeReplace creates every imported contact point as new,
+	// regardless of what's already stored.
+	ImportModeReplace ImportMode = "replace"
+)
+
+// ImportOptions configures ImportContactPoints.
+type ImportOptions struct {
+	Mode ImportMode
+	// DryRun validates the import and populates ImportReport without writing
+	// anything.
+	DryRun     bool
+	Provenance models.Provenance
+}
+
+// ImportReport summarizes the outcome of an ImportContactPoints call, one
+// entry per receiver found in the imported YAML.
+type ImportReport struct {
+	Created []string
+	Updated []string
+	Skipped []string
+	Errors  map[string]string
+}
+
+// exportedReceiver is the YAML shape used by ExportContactPoints and
+// ImportContactPoints, modeled on upstream Prometheus Alertmanager's
+// `receivers:` block as closely as the Grafana-managed receiver allows.
+type exportedReceiver struct {
+	UID                   string                 `yaml:"uid,omitempty"`
+	Name                  string                 `yaml:"name"`
+	Type                  string                 `yaml:"type"`
+	DisableResolveMessage bool                   `yaml:"disable_resolve_message,omitempty"`
+	Settings              map[string]interface{} `yaml:"settings"`
+}
+
+type exportedConfig struct {
+	Receivers []exportedReceiver `yaml:"receivers"`
+}
+
+// ExportContactPoints renders every contact point for orgID as Alertmanager-
+// compatible YAML, for use in a GitOps provisioning workflow. Secrets are
+// redacted unless includeSecrets is true; the caller must have already
+// permission-checked that before setting it.
+func (ecp *EmbeddedContactPointService) ExportContactPoints(ctx context.Context, orgID int64, includeSecrets bool) ([]byte, error) {
+	cfg, _, err := ecp.getCurrentConfig(ctx, orgID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+
+	out := exportedConfig{}
 	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
-		for index, grafanaReceiver := range receiver.GrafanaManagedReceivers {
-			if grafanaReceiver.UID == uid {
-				receiver.GrafanaManagedReceivers = append(receiver.GrafanaManagedReceivers[:index], receiver.GrafanaManagedReceivers[index+1:]...)
-				break
+		for _, gr := range receiver.GrafanaManagedReceivers {
+			settings, err := gr.Settings.Clone()
+			if err != nil {
+				return nil, fmt.Errorf("failed to export receiver %q: %w", gr.Name, err)
+			}
+			for k, v := range gr.SecureSettings {
+				value := apimodels.RedactedValue
+				if includeSecrets {
+					decrypted, err := ecp.decrypteValue(v)
+					if err != nil {
+						return nil, fmt.Errorf("failed to decrypt secret %q for receiver %q: %w", k, gr.Name, err)
+					}
+					value = decrypted
+				}
+				settings.Set(k, value)
 			}
+			out.Receivers = append(out.Receivers, exportedReceiver{
+				UID:                   gr.UID,
+				Name:                  gr.Name,
+				Type:                  gr.Type,
+				DisableResolveMessage: gr.DisableResolveMessage,
+				Settings:              settings.MustMap(),
+			})
 		}
 	}
-	data, err := json.Marshal(cfg)
+
+	return yaml.Marshal(out)
+}
+
+// ImportContactPoints round-trips a contact-point set previously produced by
+// ExportContactPoints (or hand-written to the same schema) into the org's
+// Alertmanager configuration. In ImportModeMerge, an imported receiver
+// matching a stored contact point (by UID, or by a unique (name, type) pair)
+// is updated in place; a receiver with no match is created, except one whose
+// UID or (name, type) pair can't be resolved, which is skipped. In ImportModeReplace,
+// every imported receiver is created as new. DryRun validates and reports
+// without writing.
+func (ecp *EmbeddedContactPointService) ImportContactPoints(ctx context.Context, orgID int64, data []byte, opts ImportOptions) (ImportReport, error) {
+	var parsed exportedConfig
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return ImportReport{}, fmt.Errorf("failed to parse contact points: %w", err)
+	}
+
+	report := ImportReport{Errors: map[string]string{}}
+
+	existingByUID := map[string]apimodels.EmbeddedContactPoint{}
+	existingByNameType := map[string][]apimodels.EmbeddedContactPoint{}
+	if opts.Mode == ImportModeMerge {
+		existing, err := ecp.GetContactPoints(ctx, orgID)
+		if err != nil {
+			return ImportReport{}, err
+		}
+		for _, cp := range existing {
+			existingByUID[cp.UID] = cp
+			key := cp.Name + "/" + cp.Type
+			existingByNameType[key] = append(existingByNameType[key], cp)
+		}
+	}
+
+	for _, r := range parsed.Receivers {
+		settingsJSON, err := json.Marshal(r.Settings)
+		if err != nil {
+			report.Errors[r.Name] = err.Error()
+			continue
+		}
+		settings, err := simplejson.NewJson(settingsJSON)
+		if err != nil {
+			report.Errors[r.Name] = err.Error()
+			continue
+		}
+		cp := apimodels.EmbeddedContactPoint{
+			Name:                  r.Name,
+			Type:                  r.Type,
+			DisableResolveMessage: r.DisableResolveMessage,
+			Settings:              settings,
+		}
+
+		var existingCP apimodels.EmbeddedContactPoint
+		matched := false
+		if opts.Mode == ImportModeMerge {
+			existingCP, matched, err = resolveImportMatch(r, existingByUID, existingByNameType)
+			if err != nil {
+				report.Skipped = append(report.Skipped, r.Name)
+				report.Errors[r.Name] = err.Error()
+				continue
+			}
+		}
+
+		if matched {
+			cp.UID = existingCP.UID
+			merged, err := ecp.mergeRedactedValues(ctx, orgID, cp)
+			if err != nil {
+				report.Errors[r.Name] = err.Error()
+				continue
+			}
+			if err := ecp.validateContactPoint(merged); err != nil {
+				report.Errors[r.Name] = err.Error()
+				continue
+			}
+			if opts.DryRun {
+				report.Updated = append(report.Updated, r.Name)
+				continue
+			}
+			if err := ecp.UpdateContactPoint(ctx, orgID, cp, opts.Provenance); err != nil {
+				report.Errors[r.Name] = err.Error()
+				continue
+			}
+			report.Updated = append(report.Updated, r.Name)
+			continue
+		}
+
+		// there is no stored contact point to merge redacted values from, so
+		// importing one verbatim would persist the literal "[REDACTED]"
+		// placeholder as the actual secret. Only CreateContactPoint handles
+		// this path (UpdateContactPoint merges redacted values from the
+		// stored copy), so reject it here rather than silently corrupting
+		// delivery.
+		if containsRedactedValue(r.Settings) {
+			report.Skipped = append(report.Skipped, r.Name)
+			report.Errors[r.Name] = "contains redacted secret values and no matching stored contact point to merge them from; re-export with includeSecrets to import as new"
+			continue
+		}
+
+		if err := ecp.validateContactPoint(cp); err != nil {
+			report.Errors[r.Name] = err.Error()
+			continue
+		}
+
+		if opts.DryRun {
+			report.Created = append(report.Created, r.Name)
+			continue
+		}
+		if _, err := ecp.CreateContactPoint(ctx, orgID, cp, opts.Provenance); err != nil {
+			report.Errors[r.Name] = err.Error()
+			continue
+		}
+		report.Created = append(report.Created, r.Name)
+	}
+
+	return report, nil
+}
+
+// resolveImportMatch finds the stored contact point r should be merged into,
+// if any. A UID on r is authoritative; without one, r falls back to matching
+// by (name, type), but only if that pair identifies a single stored contact
+// point, since two contact points can legitimately share both.
+func resolveImportMatch(r exportedReceiver, byUID map[string]apimodels.EmbeddedContactPoint, byNameType map[string][]apimodels.EmbeddedContactPoint) (apimodels.EmbeddedContactPoint, bool, error) {
+	if r.UID != "" {
+		cp, ok := byUID[r.UID]
+		if !ok {
+			return apimodels.EmbeddedContactPoint{}, false, fmt.Errorf("no stored contact point with uid %q", r.UID)
+		}
+		return cp, true, nil
+	}
+
+	candidates := byNameType[r.Name+"/"+r.Type]
+	switch len(candidates) {
+	case 0:
+		return apimodels.EmbeddedContactPoint{}, false, nil
+	case 1:
+		return candidates[0], true, nil
+	default:
+		return apimodels.EmbeddedContactPoint{}, false, fmt.Errorf("matches %d stored contact points named %q of type %q; re-export with uid set to disambiguate", len(candidates), r.Name, r.Type)
+	}
+}
+
+// containsRedactedValue reports whether any setting value is the literal
+// redacted placeholder ExportContactPoints emits in place of a real secret.
+func containsRedactedValue(settings map[string]interface{}) bool {
+	for _, v := range settings {
+		if s, ok := v.(string); ok && s == apimodels.RedactedValue {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretsHealthReport is the result of SecretsHealthCheck: how many secure
+// settings across an org's contact points decrypt cleanly with the currently
+// active encryption key, and which ones don't.
+type SecretsHealthReport struct {
+	OK     int
+	Failed int
+	// Errors maps "<contact point UID>/<secret key>" to the decryption error.
+	Errors map[string]string
+}
+
+// SecretsHealthCheck decrypts every secure setting stored for orgID's contact
+// points and reports how many succeed. Unlike GetContactPoints, which
+// silently skips secrets it can't decrypt, this surfaces the failures so
+// operators can tell a broken encryption key from an intentionally empty
+// secret.
+func (ecp *EmbeddedContactPointService) SecretsHealthCheck(ctx context.Context, orgID int64) (SecretsHealthReport, error) {
+	cfg, _, err := ecp.getCurrentConfig(ctx, orgID)
 	if err != nil {
-		return err
+		return SecretsHealthReport{}, err
 	}
-	return ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
-		err := ecp.provenanceStore.DeleteProvenance(ctx, orgID, &apimodels.EmbeddedContactPoint{
-			UID: uid,
-		})
+
+	report := SecretsHealthReport{Errors: map[string]string{}}
+	for _, receiver := range cfg.GetGrafanaReceiverMap() {
+		for k, v := range receiver.SecureSettings {
+			if _, err := ecp.decrypteValue(v); err != nil {
+				report.Failed++
+				report.Errors[receiver.UID+"/"+k] = err.Error()
+				continue
+			}
+			report.OK++
+		}
+	}
+	return report, nil
+}
+
+// RotateSecrets re-encrypts every secure setting stored for orgID's contact
+// points under the currently active encryption key and writes the updated
+// Alertmanager configuration back in a single transaction. Use this after
+// rolling the data-encryption key, or after migrating from an older envelope
+// format, so old ciphertext doesn't linger alongside new.
+func (ecp *EmbeddedContactPointService) RotateSecrets(ctx context.Context, orgID int64) error {
+	return ecp.withRetry(ctx, orgID, func(cfg *apimodels.PostableUserConfig, fetchedHash string) error {
+		for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+			for _, gr := range receiver.GrafanaManagedReceivers {
+				for k, v := range gr.SecureSettings {
+					decrypted, err := ecp.decrypteValue(v)
+					if err != nil {
+						return terminalError{fmt.Errorf("failed to decrypt secret %q for receiver %q (uid %q): %w", k, gr.Name, gr.UID, err)}
+					}
+					reencrypted, err := ecp.encryptValue(decrypted)
+					if err != nil {
+						return terminalError{err}
+					}
+					gr.SecureSettings[k] = reencrypted
+				}
+			}
+		}
+
+		data, err := json.Marshal(cfg)
 		if err != nil {
-			return err
+			return terminalError{err}
 		}
-		return ecp.amStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
-			AlertmanagerConfiguration: string(data),
-			FetchedConfigurationHash:  fetchedHash,
-			ConfigurationVersion:      "v1",
-			Default:                   false,
-			OrgID:                     orgID,
+
+		return ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
+			return ecp.amStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+				AlertmanagerConfiguration: string(data),
+				FetchedConfigurationHash:  fetchedHash,
+				ConfigurationVersion:      "v1",
+				Default:                   false,
+				OrgID:                     orgID,
+			})
 		})
 	})
 }
@@ -329,6 +952,92 @@ func (ecp *EmbeddedContactPointService) decrypteValue(value string) (string, err
 	return string(decryptedValue), nil
 }
 
+// tlsSecretKeys lists the secure-setting keys that carry mTLS PEM material.
+// They're handled separately because ExtractSecrtes/SecretKeys don't know
+// about them.
+func tlsSecretKeys() []string {
+	return []string{tlsConfigClientCertKey, tlsConfigClientKeyKey, tlsConfigCACertKey}
+}
+
+// extractTLSSecrets removes any present tls_config.* values from
+// contactPoint.Settings and returns them keyed the same way ExtractSecrtes
+// returns token-style secrets.
+func extractTLSSecrets(contactPoint apimodels.EmbeddedContactPoint) map[string]string {
+	out := map[string]string{}
+	for _, key := range tlsSecretKeys() {
+		value := contactPoint.Settings.Get(key).MustString()
+		if value == "" {
+			continue
+		}
+		out[key] = value
+		contactPoint.Settings.Del(key)
+	}
+	return out
+}
+
+// mergeRedactedTLSSecrets replaces any tls_config.* setting on contactPoint
+// that's still the redacted placeholder with the real value from
+// rawContactPoint.
+func mergeRedactedTLSSecrets(contactPoint, rawContactPoint apimodels.EmbeddedContactPoint) {
+	for _, key := range tlsSecretKeys() {
+		if contactPoint.Settings.Get(key).MustString() == apimodels.RedactedValue {
+			contactPoint.Settings.Set(key, rawContactPoint.Settings.Get(key).MustString())
+		}
+	}
+}
+
+// validateTLSConfig checks any mTLS secure settings present on contactPoint:
+// the client certificate and key must parse and form a valid pair, and the CA
+// bundle (if supplied) must contain at least one valid PEM certificate. It
+// also logs a warning when the client certificate is close to expiry. It is a
+// no-op when none of the tls_config.* settings are present.
+//
+// Callers must replace a redacted value with the real stored one (see
+// mergeRedactedTLSSecrets) before calling this; a field that's still the
+// literal RedactedValue here has nothing to merge from and is rejected.
+func (ecp *EmbeddedContactPointService) validateTLSConfig(contactPoint apimodels.EmbeddedContactPoint) error {
+	clientCert := contactPoint.Settings.Get(tlsConfigClientCertKey).MustString()
+	clientKey := contactPoint.Settings.Get(tlsConfigClientKeyKey).MustString()
+	caCert := contactPoint.Settings.Get(tlsConfigCACertKey).MustString()
+
+	if clientCert == apimodels.RedactedValue {
+		return fmt.Errorf("%s is redacted and cannot be saved as-is", tlsConfigClientCertKey)
+	}
+	if clientKey == apimodels.RedactedValue {
+		return fmt.Errorf("%s is redacted and cannot be saved as-is", tlsConfigClientKeyKey)
+	}
+	if caCert == apimodels.RedactedValue {
+		return fmt.Errorf("%s is redacted and cannot be saved as-is", tlsConfigCACertKey)
+	}
+
+	if (clientCert == "") != (clientKey == "") {
+		return fmt.Errorf("%s and %s must be provided together", tlsConfigClientCertKey, tlsConfigClientKeyKey)
+	}
+
+	if clientCert != "" {
+		pair, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return fmt.Errorf("invalid client certificate/key pair: %w", err)
+		}
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		if time.Until(leaf.NotAfter) < tlsCertExpiryWarning {
+			ecp.log.Warn("contact point client certificate is expiring soon", "notAfter", leaf.NotAfter)
+		}
+	}
+
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return fmt.Errorf("%s does not contain a valid PEM certificate", tlsConfigCACertKey)
+		}
+	}
+
+	return nil
+}
+
 func (ecp *EmbeddedContactPointService) encryptValue(value string) (string, error) {
 	encryptedData, err := ecp.encryptionService.Encrypt(context.Background(), []byte(value), secrets.WithoutScope())
 	if err != nil {
@@ -0,0 +1,199 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/prometheus/alertmanager/config"
+)
+
+// fakeAlertingStore is a minimal in-memory store.AlertingStore that enforces
+// the same hash-based optimistic concurrency check the real store does, so
+// withRetry's retry-on-conflict behavior can be exercised without a database.
+type fakeAlertingStore struct {
+	mtx  sync.Mutex
+	data string
+	hash int
+}
+
+func newFakeAlertingStore(cfg *apimodels.PostableUserConfig) *fakeAlertingStore {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return &fakeAlertingStore{data: string(data)}
+}
+
+func (f *fakeAlertingStore) GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	query.Result = &models.AlertConfiguration{
+		AlertmanagerConfiguration: f.data,
+		ConfigurationHash:         fmt.Sprintf("%d", f.hash),
+	}
+	return nil
+}
+
+func (f *fakeAlertingStore) UpdateAlertmanagerConfiguration(ctx context.Context, cmd *models.SaveAlertmanagerConfigurationCmd) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if cmd.FetchedConfigurationHash != fmt.Sprintf("%d", f.hash) {
+		return fmt.Errorf("configuration version mismatch")
+	}
+	f.data = cmd.AlertmanagerConfiguration
+	f.hash++
+	return nil
+}
+
+// TestWithRetry_ConcurrentWritesDontLoseUpdates fires N concurrent attempts
+// that each append one receiver to the same org's Alertmanager configuration
+// through withRetry, and asserts every one of them landed: none were
+// silently lost to the read-modify-write race the per-org lock and retry
+// loop exist to close.
+func TestWithRetry_ConcurrentWritesDontLoseUpdates(t *testing.T) {
+	const orgID = 1
+	const n = 20
+
+	store := newFakeAlertingStore(&apimodels.PostableUserConfig{})
+	ecp := &EmbeddedContactPointService{
+		amStore: store,
+		orgMtx:  map[int64]*sync.Mutex{},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("receiver-%d", i)
+			err := ecp.withRetry(context.Background(), orgID, func(cfg *apimodels.PostableUserConfig, fetchedHash string) error {
+				cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, &apimodels.PostableApiReceiver{
+					Receiver: config.Receiver{Name: name},
+				})
+				data, err := json.Marshal(cfg)
+				if err != nil {
+					return terminalError{err}
+				}
+				return store.UpdateAlertmanagerConfiguration(context.Background(), &models.SaveAlertmanagerConfigurationCmd{
+					AlertmanagerConfiguration: string(data),
+					FetchedConfigurationHash:  fetchedHash,
+					ConfigurationVersion:      "v1",
+					OrgID:                     orgID,
+				})
+			})
+			if err != nil {
+				t.Errorf("withRetry for %s: %v", name, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var final apimodels.PostableUserConfig
+	if err := json.Unmarshal([]byte(store.data), &final); err != nil {
+		t.Fatalf("failed to unmarshal final config: %v", err)
+	}
+	if len(final.AlertmanagerConfig.Receivers) != n {
+		t.Fatalf("expected %d receivers, got %d (lost writes)", n, len(final.AlertmanagerConfig.Receivers))
+	}
+}
+
+// conflictInjectingStore wraps fakeAlertingStore to simulate another writer
+// landing in between withRetry's first read and its write: the first read
+// triggers a direct store update (bumping the hash), so that attempt's write
+// is built against a hash that's already stale by the time it runs.
+type conflictInjectingStore struct {
+	*fakeAlertingStore
+	injected bool
+}
+
+func (s *conflictInjectingStore) GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error {
+	if err := s.fakeAlertingStore.GetLatestAlertmanagerConfiguration(ctx, query); err != nil {
+		return err
+	}
+	if !s.injected {
+		s.injected = true
+		if err := s.fakeAlertingStore.UpdateAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+			AlertmanagerConfiguration: query.Result.AlertmanagerConfiguration,
+			FetchedConfigurationHash:  query.Result.ConfigurationHash,
+		}); err != nil {
+			panic(err)
+		}
+	}
+	return nil
+}
+
+// TestWithRetry_GenuineConflictRecoversViaRetry proves the retry path
+// isWriteConflict/maxConfigWriteAttempts exist for actually works: forced to
+// observe a real hash mismatch, withRetry re-reads the configuration and
+// succeeds on its second attempt instead of giving up or silently dropping
+// the write.
+func TestWithRetry_GenuineConflictRecoversViaRetry(t *testing.T) {
+	const orgID = 1
+	store := &conflictInjectingStore{fakeAlertingStore: newFakeAlertingStore(&apimodels.PostableUserConfig{})}
+	ecp := &EmbeddedContactPointService{
+		amStore: store,
+		orgMtx:  map[int64]*sync.Mutex{},
+	}
+
+	attempts := 0
+	err := ecp.withRetry(context.Background(), orgID, func(cfg *apimodels.PostableUserConfig, fetchedHash string) error {
+		attempts++
+		cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, &apimodels.PostableApiReceiver{
+			Receiver: config.Receiver{Name: "receiver"},
+		})
+		data, err := json.Marshal(cfg)
+		if err != nil {
+			return terminalError{err}
+		}
+		return store.UpdateAlertmanagerConfiguration(context.Background(), &models.SaveAlertmanagerConfigurationCmd{
+			AlertmanagerConfiguration: string(data),
+			FetchedConfigurationHash:  fetchedHash,
+			ConfigurationVersion:      "v1",
+			OrgID:                     orgID,
+		})
+	})
+	if err != nil {
+		t.Fatalf("withRetry did not recover from a genuine conflict: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected the conflict to force exactly one retry (2 attempts total), got %d", attempts)
+	}
+}
+
+// Create/UpdateContactPoint and DeleteContactPoint aren't exercised directly
+// here: beyond amStore, they depend on ProvisioningStore, TransactionManager,
+// and secrets.Service/apimodels.EmbeddedContactPoint behavior (IsValid,
+// ExtractSecrtes, SecretKeys) that live outside this snapshot, so a fake for
+// them can't be written with any confidence it matches the real interfaces.
+// withRetry is the piece those methods share and delegate their concurrency
+// handling to, and it's covered above.
+
+// TestWithRetry_NonConflictErrorIsNotRetried asserts that a failure
+// unrelated to a configuration-hash conflict (e.g. a transient store error)
+// is returned to the caller immediately, not retried and then relabeled as
+// ErrConcurrentModification.
+func TestWithRetry_NonConflictErrorIsNotRetried(t *testing.T) {
+	store := newFakeAlertingStore(&apimodels.PostableUserConfig{})
+	ecp := &EmbeddedContactPointService{
+		amStore: store,
+		orgMtx:  map[int64]*sync.Mutex{},
+	}
+
+	attempts := 0
+	wantErr := fmt.Errorf("boom")
+	err := ecp.withRetry(context.Background(), 1, func(cfg *apimodels.PostableUserConfig, fetchedHash string) error {
+		attempts++
+		return wantErr
+	})
+	if attempts != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-conflict error, got %d", attempts)
+	}
+	if err != wantErr {
+		t.Fatalf("expected the original error to be returned unwrapped, got %v", err)
+	}
+}